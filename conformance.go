@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// runConformanceHTTP drives every vector in dir against a live server at
+// baseURL (POST /receipts/process, then GET .../points?breakdown=1) so
+// alternate implementations of this API can be checked for compliance.
+// When record is true, mismatches are treated as authoritative: the
+// vector file is rewritten with whatever the server actually returned.
+// Vectors marked expectInvalid are never rewritten; a mismatch there counts
+// as a failure in either mode.
+func runConformanceHTTP(baseURL, dir string, record bool) (passed, failed int, err error) {
+	paths, err := loadVectorFiles(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	client := &http.Client{}
+
+	for _, path := range paths {
+		v, err := loadVector(path)
+		if err != nil {
+			return passed, failed, err
+		}
+
+		points, breakdown, status, err := postAndGet(client, baseURL, v.Receipt)
+		if err != nil {
+			return passed, failed, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if v.ExpectInvalid {
+			if status == http.StatusBadRequest {
+				passed++
+			} else {
+				failed++
+				fmt.Printf("FAIL %s: expected 400, got %d\n", path, status)
+			}
+			continue
+		}
+
+		mismatch := status != http.StatusOK ||
+			points != v.ExpectedPoints ||
+			(v.ExpectedBreakdown != nil && !reflect.DeepEqual(breakdown, v.ExpectedBreakdown))
+
+		if !mismatch {
+			passed++
+			continue
+		}
+
+		if !record {
+			failed++
+			fmt.Printf("FAIL %s: points = %d, want %d\n", path, points, v.ExpectedPoints)
+			continue
+		}
+
+		v.ExpectedPoints = points
+		v.ExpectedBreakdown = breakdown
+		if err := saveVector(path, v); err != nil {
+			return passed, failed, fmt.Errorf("recording %s: %w", path, err)
+		}
+		fmt.Printf("RECORDED %s\n", path)
+		passed++
+	}
+
+	return passed, failed, nil
+}
+
+func postAndGet(client *http.Client, baseURL string, receipt Receipt) (points int, breakdown map[string]int, status int, err error) {
+	body, err := json.Marshal(receipt)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	resp, err := client.Post(baseURL+"/receipts/process", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil, resp.StatusCode, nil
+	}
+
+	var processed ProcessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&processed); err != nil {
+		return 0, nil, resp.StatusCode, err
+	}
+
+	pointsResp, err := client.Get(baseURL + "/receipts/" + processed.Id.String() + "/points?breakdown=1")
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer pointsResp.Body.Close()
+
+	var decoded PointsResponse
+	if err := json.NewDecoder(pointsResp.Body).Decode(&decoded); err != nil {
+		return 0, nil, pointsResp.StatusCode, err
+	}
+
+	return decoded.Points, decoded.Breakdown, pointsResp.StatusCode, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// idempotencyCache remembers which receipt id was assigned for an
+// Idempotency-Key, for a limited TTL, so a client retrying
+// POST /receipts/process after a dropped response gets back the same id
+// instead of scoring (and storing) a duplicate.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	id        string
+	expiresAt time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{ttl: ttl, entries: make(map[string]idempotencyEntry)}
+}
+
+func (c *idempotencyCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.id, true
+}
+
+func (c *idempotencyCache) put(key, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = idempotencyEntry{id: id, expiresAt: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCacheRoundTrip(t *testing.T) {
+	c := newIdempotencyCache(time.Minute)
+
+	if _, ok := c.get("key-1"); ok {
+		t.Fatal("get on an empty cache returned a hit")
+	}
+
+	c.put("key-1", "abc")
+
+	id, ok := c.get("key-1")
+	if !ok || id != "abc" {
+		t.Fatalf("get(key-1) = (%q, %v), want (abc, true)", id, ok)
+	}
+}
+
+func TestIdempotencyCacheExpires(t *testing.T) {
+	c := newIdempotencyCache(-time.Second)
+	c.put("key-1", "abc")
+
+	if _, ok := c.get("key-1"); ok {
+		t.Fatal("get returned a hit for an already-expired entry")
+	}
+}
@@ -2,45 +2,25 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
 	"os"
-	"strconv"
-	"strings"
-	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// map in lieu of real DB
-type DB struct {
-	Db   map[string]int
-	Lock sync.RWMutex
-}
-
-var db DB
+var store Store
+var engine *RuleEngine
+var idempotency *idempotencyCache
+var metrics *Metrics
+var adminToken string
 var logger *log.Logger = log.New(os.Stdout, "receipt-processor > ", 0)
 
-func NewDB() DB {
-	return DB{Db: make(map[string]int), Lock: sync.RWMutex{}}
-}
-
-func (db *DB) Get(id string) int {
-	db.Lock.RLock()
-	defer db.Lock.RUnlock()
-
-	return db.Db[id]
-}
-
-func (db *DB) Put(id string, points int) {
-	db.Lock.Lock()
-	defer db.Lock.Unlock()
-
-	db.Db[id] = points
-}
+const legacySnapshotPath = "receipts.json"
 
 type Receipt struct {
 	Retailer     string `json:"retailer"`
@@ -60,13 +40,73 @@ type ProcessResponse struct {
 }
 
 type PointsResponse struct {
-	Points int `json:"points"`
+	Points    int            `json:"points"`
+	Breakdown map[string]int `json:"breakdown,omitempty"`
+}
+
+// ValidationErrorResponse is the 400 body returned when a receipt fails
+// validation; it carries every failing field at once.
+type ValidationErrorResponse struct {
+	Errors ValidationErrors `json:"errors"`
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs ValidationErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ValidationErrorResponse{Errors: errs})
 }
 
 func main() {
-	db = NewDB()
+	kind := flag.String("store", envOr("STORE_KIND", string(StoreKindMemory)), "storage backend: memory, bolt, jetstream")
+	boltPath := flag.String("bolt-path", envOr("BOLT_PATH", "receipts.db"), "path to the bbolt database file (store=bolt)")
+	natsURL := flag.String("nats-url", envOr("NATS_URL", DefaultNATSURL), "NATS server URL (store=jetstream)")
+	natsEnv := flag.String("nats-env", envOr("NATS_ENV", "dev"), "environment suffix for the JetStream KV bucket (store=jetstream)")
+	kvTTL := flag.Duration("kv-ttl", envDurationOr("KV_TTL", 0), "TTL for stored points, 0 disables expiry (store=jetstream)")
+	snapshotEvery := flag.Duration("snapshot-interval", envDurationOr("SNAPSHOT_INTERVAL", 30*time.Second), "how often the in-memory backend snapshots to disk during dev (store=memory)")
+	rulesPath := flag.String("rules", envOr("RULES_PATH", ""), "path to a rule-set config file (YAML or JSON); built-in rules are used if unset")
+	vectorsDir := flag.String("vectors-dir", "", "run the conformance test-vector corpus in this directory against a live HTTP endpoint instead of serving")
+	baseURL := flag.String("base-url", "http://localhost:8080", "base URL of the live endpoint to check (used with --vectors-dir)")
+	record := flag.Bool("record", false, "with --vectors-dir, overwrite each vector's expected fields with what the server actually returned")
+	idempotencyTTL := flag.Duration("idempotency-ttl", envDurationOr("IDEMPOTENCY_TTL", 5*time.Minute), "how long an Idempotency-Key is remembered for POST /receipts/process")
+	token := flag.String("admin-token", envOr("ADMIN_TOKEN", ""), "bearer token required for GET /receipts; empty disables the check")
+	flag.Parse()
+
+	adminToken = *token
+	idempotency = newIdempotencyCache(*idempotencyTTL)
+	metrics = NewMetrics()
+
+	if *vectorsDir != "" {
+		passed, failed, err := runConformanceHTTP(*baseURL, *vectorsDir, *record)
+		if err != nil {
+			logger.Printf("conformance run failed: %s", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d passed, %d failed\n", passed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
-	err := serve()
+	s, err := newStore(*kind, *boltPath, *natsURL, *natsEnv, *kvTTL, *snapshotEvery)
+	if err != nil {
+		logger.Printf("failed to initialize store: %s", err)
+		os.Exit(1)
+	}
+	store = s
+	defer store.Close()
+
+	if *rulesPath != "" {
+		engine, err = LoadRuleEngine(*rulesPath)
+		if err != nil {
+			logger.Printf("failed to load rules from %s: %s", *rulesPath, err)
+			os.Exit(1)
+		}
+	} else {
+		engine = DefaultRuleEngine()
+	}
+
+	err = serve()
 	if err == http.ErrServerClosed {
 		logger.Print("server closed")
 	} else if err != nil {
@@ -75,142 +115,191 @@ func main() {
 	}
 }
 
-func getPoints(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if id == "" {
-		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
-		return
+// newStore constructs the configured Store and, for backends other than
+// memory (which handles its own dev snapshotting), migrates a legacy
+// receipts.json snapshot in if the backend is otherwise empty.
+func newStore(kind, boltPath, natsURL, natsEnv string, kvTTL, snapshotEvery time.Duration) (Store, error) {
+	switch StoreKind(kind) {
+	case StoreKindMemory:
+		return NewMemoryStore(legacySnapshotPath, snapshotEvery)
+	case StoreKindBolt:
+		s, err := NewBoltStore(boltPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := migrateLegacySnapshot(s); err != nil {
+			s.Close()
+			return nil, err
+		}
+		return s, nil
+	case StoreKindJetStream:
+		s, err := NewJetStreamStore(natsURL, natsEnv, kvTTL)
+		if err != nil {
+			return nil, err
+		}
+		if err := migrateLegacySnapshot(s); err != nil {
+			s.Close()
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown store kind %q", kind)
 	}
+}
 
-	points := db.Get(id)
-
-	response, err := json.Marshal(PointsResponse{Points: points})
+// migrateLegacySnapshot loads legacySnapshotPath into s if it exists and s
+// has no entries yet, so switching backends doesn't silently drop data
+// left over from an earlier in-memory deployment. It understands both the
+// current snapshot format (map of id to StoredReceipt) and the points-only
+// format written before receipts themselves were persisted.
+func migrateLegacySnapshot(s Store) error {
+	raw, err := os.ReadFile(legacySnapshotPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
-		http.Error(w, "no receipt found for that id", http.StatusNotFound)
-		return
+		return err
 	}
 
-	logger.Printf("got request in get points\npoints: %d\n", points)
-	io.WriteString(w, string(response))
+	if existing, _, err := s.List("", 1); err != nil {
+		return err
+	} else if len(existing) > 0 {
+		return nil
+	}
+
+	snapshot := make(map[string]StoredReceipt)
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		var legacy map[string]int
+		if err := json.Unmarshal(raw, &legacy); err != nil {
+			return err
+		}
+		for id, points := range legacy {
+			snapshot[id] = StoredReceipt{Points: points, CreatedAt: time.Now().UTC()}
+		}
+	}
+
+	for id, record := range snapshot {
+		if _, ok, err := s.Get(id); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		record := record
+		if err := s.Put(id, &record); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func postProcessReceipt(w http.ResponseWriter, r *http.Request) {
-	// denying unwanted requests
-	if r.Method != "POST" {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-		return
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
+
+func envDurationOr(key string, fallback time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
 	}
+	return fallback
+}
 
-	// unmarshall the request and send the points awarded to db
-	payload := &Receipt{}
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
+func getPoints(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
 		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
 		return
 	}
 
-	json.Unmarshal(body, payload)
-
-	points, err := countPoints(payload)
+	record, ok, err := store.Get(id)
 	if err != nil {
-		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		http.Error(w, "failed to look up that receipt", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "no receipt found for that id", http.StatusNotFound)
 		return
 	}
 
-	// generate uuid for the points
-	id := uuid.New()
-	db.Put(id.String(), points)
+	resp := PointsResponse{Points: record.Points}
+	if r.URL.Query().Get("breakdown") == "1" {
+		resp.Breakdown = record.Breakdown
+	}
 
-	response, err := json.Marshal(ProcessResponse{Id: id})
+	logger.Printf("got request in get points\npoints: %d\n", record.Points)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleScoringRules reports the currently loaded rule set so clients can
+// introspect how points are being awarded without guessing at server config.
+func handleScoringRules(w http.ResponseWriter, r *http.Request) {
+	response, err := json.Marshal(RulesFile{Rules: engine.Configs()})
 	if err != nil {
-		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		http.Error(w, "failed to describe the loaded rules", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Printf("got request in process receipt\nid: %s, points: %d\n", id, points)
 	io.WriteString(w, string(response))
 }
 
-// counts the points awarded by the receipt
-func countPoints(r *Receipt) (int, error) {
-	points := 0
-
-	dollarsAndCents := strings.Split(r.Total, ".")
-	// guaranteed to have 999.99 format as per api spec
-	// so can split into dollar and cent safely
-	totalCent, err := strconv.ParseInt(dollarsAndCents[1], 10, 0)
-	if err != nil {
-		return 0, err
+func postProcessReceipt(w http.ResponseWriter, r *http.Request) {
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" {
+		if id, ok := idempotency.get(idempotencyKey); ok {
+			writeJSON(w, http.StatusOK, ProcessResponse{Id: mustParseUUID(id)})
+			return
+		}
 	}
 
-	// rules for point scoring
-	// A: 1 per char in retailer
-	// B: 50 if total is round to the dollar
-	// C: 25 if total is ~.00 ~.25, ~.50, ~.75
-	// D: 5 per 2 items
-	// E: trim description, if len%3 == 0, add ceiling of price*0.2 points
-	// F: 6 if day in purchase date is odd
-	// G: 10 if purchase time is AFTER 14:00 and BEFORE 16:00 (unclear if 14:00 & 16:00 is counted)
-
-	// A
-	points += len(r.Retailer)
-
-	// B
-	if totalCent == 0 {
-		points += 50
+	// unmarshall the request and send the points awarded to db
+	payload := &Receipt{}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		return
 	}
 
-	// C
-	if totalCent%25 == 0 {
-		points += 25
+	if err := json.Unmarshal(body, payload); err != nil {
+		writeValidationErrors(w, ValidationErrors{{Error: "invalid_json", Message: err.Error()}})
+		return
 	}
 
-	// D
-	points += 5 * (len(r.Items) / 2)
-
-	// E
-	for _, item := range r.Items {
-		description := strings.TrimSpace(item.ShortDescription)
-		if len(description)%3 == 0 {
-			itemPrice, err := strconv.ParseFloat(item.Price, 64)
-			if err != nil {
-				return 0, err
-			}
-
-			points += int(math.Ceil(itemPrice * 0.2))
-		}
+	normalized, validationErrs := Validate(payload)
+	if validationErrs != nil {
+		writeValidationErrors(w, validationErrs)
+		return
 	}
 
-	// F
-	dayOfPurchase, err := strconv.ParseInt(strings.Split(r.PurchaseDate, "-")[2], 10, 0)
+	points, breakdown, err := engine.Score(normalized)
 	if err != nil {
-		return 0, err
+		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		return
 	}
 
-	if (dayOfPurchase & 0b1) == 1 {
-		points += 6
+	// generate uuid for the points
+	id := uuid.New()
+	record := &StoredReceipt{
+		Receipt:   *payload,
+		Points:    points,
+		Breakdown: breakdown,
+		CreatedAt: time.Now().UTC(),
 	}
-
-	// G
-	timeofPurchase := strings.Split(r.PurchaseTime, ":")
-	purchaseHour, err := strconv.ParseInt(timeofPurchase[0], 10, 0)
-	if err != nil {
-		return 0, err
+	if err := store.Put(id.String(), record); err != nil {
+		http.Error(w, "failed to store that receipt", http.StatusInternalServerError)
+		return
 	}
 
-	if purchaseHour >= 14 && purchaseHour <= 16 {
-		points += 10
+	if idempotencyKey != "" {
+		idempotency.put(idempotencyKey, id.String())
 	}
 
-	return points, nil
-}
-
-func serve() error {
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/receipts/process", postProcessReceipt)
-	mux.HandleFunc("/receipts/{id}/points", getPoints)
+	metrics.ObserveReceiptProcessed(points)
 
-	fmt.Println("Serving on localhost:8080")
-	return http.ListenAndServe(":8080", mux)
+	logger.Printf("got request in process receipt\nid: %s, points: %d\n", id, points)
+	writeJSON(w, http.StatusOK, ProcessResponse{Id: id})
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds every collector the server exposes on /metrics.
+type Metrics struct {
+	registry          *prometheus.Registry
+	receiptsProcessed prometheus.Counter
+	pointsAwarded     prometheus.Histogram
+	handlerLatency    *prometheus.HistogramVec
+}
+
+// NewMetrics builds and registers the server's Prometheus collectors.
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+		receiptsProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "receipt_processor_receipts_processed_total",
+			Help: "Total number of receipts successfully scored.",
+		}),
+		pointsAwarded: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "receipt_processor_points_awarded",
+			Help:    "Distribution of points awarded per processed receipt.",
+			Buckets: prometheus.LinearBuckets(0, 20, 10),
+		}),
+		handlerLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "receipt_processor_handler_latency_seconds",
+			Help:    "Request latency in seconds, by route/method/status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+	}
+
+	registry.MustRegister(m.receiptsProcessed, m.pointsAwarded, m.handlerLatency)
+	return m
+}
+
+// ObserveRequest records one completed request's latency.
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	m.handlerLatency.WithLabelValues(route, method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveReceiptProcessed records one successfully scored receipt.
+func (m *Metrics) ObserveReceiptProcessed(points int) {
+	m.receiptsProcessed.Inc()
+	m.pointsAwarded.Observe(float64(points))
+}
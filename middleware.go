@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const requestIDContextKey contextKey = iota
+
+// middleware wraps a handler with cross-cutting behavior. Chains compose
+// outside-in: chain(h, a, b) runs a, then b, then h.
+type middleware func(http.Handler) http.Handler
+
+func chain(h http.Handler, mws ...middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// requestIDMiddleware echoes the caller's X-Request-ID, or generates one,
+// and makes it available to downstream middleware/handlers via context and
+// the response header.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDContextKey).(string)
+	return id
+}
+
+// statusRecorder captures the status code and byte count a handler wrote,
+// since http.ResponseWriter doesn't expose either.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// accessLogMiddleware logs one structured JSON line per request via slog,
+// and records the per-route latency histogram.
+func accessLogMiddleware(logger *slog.Logger, metrics *Metrics) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			duration := time.Since(start)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			logger.Info("request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"duration_ms", duration.Milliseconds(),
+				"bytes", rec.bytes,
+				"request_id", requestID(r),
+			)
+
+			route := r.Pattern
+			if route == "" {
+				route = r.URL.Path
+			}
+			metrics.ObserveRequest(route, r.Method, rec.status, duration)
+		})
+	}
+}
+
+// recoveryMiddleware turns a panic in a handler into a 500 response
+// carrying the request id, instead of crashing the server.
+func recoveryMiddleware(logger *slog.Logger) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.Error("panic recovered", "error", rec, "request_id", requestID(r))
+					http.Error(w, "internal error, request_id="+requestID(r), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// perIPRateLimiter hands out a token-bucket rate.Limiter per client IP,
+// creating one lazily on first use. It's meant to guard a single
+// expensive route (POST /receipts/process), not the whole server.
+type perIPRateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	rps      rate.Limit
+	burst    int
+}
+
+func newPerIPRateLimiter(rps float64, burst int) *perIPRateLimiter {
+	return &perIPRateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		rps:      rate.Limit(rps),
+		burst:    burst,
+	}
+}
+
+func (l *perIPRateLimiter) allow(ip string) bool {
+	l.mu.Lock()
+	limiter, ok := l.limiters[ip]
+	if !ok {
+		limiter = rate.NewLimiter(l.rps, l.burst)
+		l.limiters[ip] = limiter
+	}
+	l.mu.Unlock()
+
+	return limiter.Allow()
+}
+
+// rateLimitMiddleware rejects requests over the per-IP limit with 429.
+func rateLimitMiddleware(limiter *perIPRateLimiter) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+			if !limiter.allow(ip) {
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
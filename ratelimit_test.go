@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestPerIPRateLimiterAllow(t *testing.T) {
+	l := newPerIPRateLimiter(1, 2)
+
+	if !l.allow("1.2.3.4") {
+		t.Fatal("first request within burst was rejected")
+	}
+	if !l.allow("1.2.3.4") {
+		t.Fatal("second request within burst was rejected")
+	}
+	if l.allow("1.2.3.4") {
+		t.Fatal("request beyond burst was allowed")
+	}
+}
+
+func TestPerIPRateLimiterTracksIPsIndependently(t *testing.T) {
+	l := newPerIPRateLimiter(1, 1)
+
+	if !l.allow("1.2.3.4") {
+		t.Fatal("first IP's initial request was rejected")
+	}
+	if !l.allow("5.6.7.8") {
+		t.Fatal("second IP's initial request was rejected, limiter state leaked across IPs")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultListLimit = 20
+
+// ReceiptResponse is the body returned by GET/PUT /receipts/{id}: the
+// original receipt alongside the points it scored.
+type ReceiptResponse struct {
+	Receipt   Receipt        `json:"receipt"`
+	Points    int            `json:"points"`
+	Breakdown map[string]int `json:"breakdown,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// ListReceiptsResponse is the paginated body returned by GET /receipts.
+type ListReceiptsResponse struct {
+	Receipts   []StoreRecord `json:"receipts"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+func handleGetReceipt(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	record, ok, err := store.Get(id)
+	if err != nil {
+		http.Error(w, "failed to look up that receipt", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receiptResponse(record))
+}
+
+func handlePutReceipt(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		return
+	}
+
+	payload := &Receipt{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		writeValidationErrors(w, ValidationErrors{{Error: "invalid_json", Message: err.Error()}})
+		return
+	}
+
+	normalized, validationErrs := Validate(payload)
+	if validationErrs != nil {
+		writeValidationErrors(w, validationErrs)
+		return
+	}
+
+	points, breakdown, err := engine.Score(normalized)
+	if err != nil {
+		http.Error(w, "The receipt is invalid", http.StatusBadRequest)
+		return
+	}
+
+	createdAt := time.Now().UTC()
+	if existing, ok, err := store.Get(id); err == nil && ok {
+		createdAt = existing.CreatedAt
+	}
+
+	record := &StoredReceipt{
+		Receipt:   *payload,
+		Points:    points,
+		Breakdown: breakdown,
+		CreatedAt: createdAt,
+	}
+	if err := store.Put(id, record); err != nil {
+		http.Error(w, "failed to store that receipt", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receiptResponse(record))
+}
+
+func handleDeleteReceipt(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if _, ok, err := store.Get(id); err != nil {
+		http.Error(w, "failed to look up that receipt", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := store.Delete(id); err != nil {
+		http.Error(w, "failed to delete that receipt", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleListReceipts(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	records, next, err := store.List(r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		http.Error(w, "failed to list receipts", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ListReceiptsResponse{Receipts: records, NextCursor: next})
+}
+
+func receiptResponse(record *StoredReceipt) ReceiptResponse {
+	return ReceiptResponse{
+		Receipt:   record.Receipt,
+		Points:    record.Points,
+		Breakdown: record.Breakdown,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// requireBearerToken gates next behind an "Authorization: Bearer <token>"
+// header. An empty token disables the check, since admin auth is optional.
+func requireBearerToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// mustParseUUID panics on a malformed UUID; only used on strings this
+// server itself generated with uuid.New().
+func mustParseUUID(s string) uuid.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
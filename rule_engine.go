@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleConfig is the on-disk description of a single rule: a type
+// identifying which constructor to use, plus whichever of its parameters
+// apply. Unused fields are omitted by the zero value and simply ignored
+// by constructors that don't need them.
+type RuleConfig struct {
+	Type       string  `json:"type" yaml:"type"`
+	Bonus      int     `json:"bonus,omitempty" yaml:"bonus,omitempty"`
+	Per        int     `json:"per,omitempty" yaml:"per,omitempty"`
+	Modulo     int     `json:"modulo,omitempty" yaml:"modulo,omitempty"`
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	Start      string  `json:"start,omitempty" yaml:"start,omitempty"`
+	End        string  `json:"end,omitempty" yaml:"end,omitempty"`
+	Threshold  float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Percent    float64 `json:"percent,omitempty" yaml:"percent,omitempty"`
+	VATPercent float64 `json:"vatPercent,omitempty" yaml:"vatPercent,omitempty"`
+}
+
+// RulesFile is the top-level shape of a --rules config file.
+type RulesFile struct {
+	Rules []RuleConfig `json:"rules" yaml:"rules"`
+}
+
+// RuleEngine scores a receipt against a loaded, ordered set of rules and
+// can report per-rule contributions alongside the total.
+type RuleEngine struct {
+	rules   []Rule
+	configs []RuleConfig
+}
+
+// DefaultRuleEngine returns the built-in rule set used when no --rules
+// file is given: the original seven scoring rules.
+func DefaultRuleEngine() *RuleEngine {
+	configs := []RuleConfig{
+		{Type: "retailerAlnum"},
+		{Type: "roundDollar", Bonus: 50},
+		{Type: "quarterMultiple", Bonus: 25},
+		{Type: "itemPair", Per: 2, Bonus: 5},
+		{Type: "descriptionMultiple", Modulo: 3, Multiplier: 0.2},
+		{Type: "oddDay", Bonus: 6},
+		{Type: "timeWindow", Start: "14:00", End: "16:00", Bonus: 10},
+	}
+
+	engine, err := buildRuleEngine(configs)
+	if err != nil {
+		// The default config is constant and known-good; a failure here
+		// would be a programming error, not a runtime condition.
+		panic(err)
+	}
+	return engine
+}
+
+// LoadRuleEngine reads a rule-set config file (YAML if the path ends in
+// .yaml/.yml, JSON otherwise) and builds the engine it describes.
+func LoadRuleEngine(path string) (*RuleEngine, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file RulesFile
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		err = yaml.Unmarshal(raw, &file)
+	} else {
+		err = json.Unmarshal(raw, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing rules file %s: %w", path, err)
+	}
+
+	return buildRuleEngine(file.Rules)
+}
+
+func buildRuleEngine(configs []RuleConfig) (*RuleEngine, error) {
+	rules := make([]Rule, 0, len(configs))
+	for _, cfg := range configs {
+		rule, err := newRule(cfg)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return &RuleEngine{rules: rules, configs: configs}, nil
+}
+
+func newRule(cfg RuleConfig) (Rule, error) {
+	switch cfg.Type {
+	case "retailerAlnum":
+		return RetailerAlnumRule{}, nil
+	case "roundDollar":
+		return RoundDollarRule{Bonus: cfg.Bonus}, nil
+	case "quarterMultiple":
+		return QuarterMultipleRule{Bonus: cfg.Bonus}, nil
+	case "itemPair":
+		return ItemPairRule{Per: cfg.Per, Bonus: cfg.Bonus}, nil
+	case "descriptionMultiple":
+		return DescriptionMultipleRule{Modulo: cfg.Modulo, Multiplier: cfg.Multiplier}, nil
+	case "oddDay":
+		return OddDayRule{Bonus: cfg.Bonus}, nil
+	case "timeWindow":
+		return TimeWindowRule{Start: cfg.Start, End: cfg.End, Bonus: cfg.Bonus}, nil
+	case "percentOfTotal":
+		return PercentOfTotalRule{Threshold: cfg.Threshold, Percent: cfg.Percent}, nil
+	case "tax":
+		return TaxRule{VATPercent: cfg.VATPercent}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", cfg.Type)
+	}
+}
+
+// Score runs every rule against r and returns the total points plus a
+// per-rule breakdown keyed by rule name.
+func (e *RuleEngine) Score(r *NormalizedReceipt) (int, map[string]int, error) {
+	total := 0
+	breakdown := make(map[string]int, len(e.rules))
+
+	for _, rule := range e.rules {
+		points, err := rule.Score(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		breakdown[rule.Name()] += points
+		total += points
+	}
+
+	return total, breakdown, nil
+}
+
+// Configs returns the rule-set config this engine was built from, for
+// serving back over /scoring/rules.
+func (e *RuleEngine) Configs() []RuleConfig {
+	return e.configs
+}
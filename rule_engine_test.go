@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestDefaultRuleEngineScore(t *testing.T) {
+	r := &Receipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        "1.25",
+		Items: []Item{
+			{ShortDescription: "Pepsi - 12-oz", Price: "1.25"},
+		},
+	}
+
+	normalized, errs := Validate(r)
+	if errs != nil {
+		t.Fatalf("Validate: %v", errs)
+	}
+
+	points, breakdown, err := DefaultRuleEngine().Score(normalized)
+	if err != nil {
+		t.Fatalf("Score: %s", err)
+	}
+
+	// Target (6 alnum) + quarterMultiple (25) + oddDay (6) = 37
+	if points != 37 {
+		t.Fatalf("points = %d, want 37", points)
+	}
+	if breakdown["retailerAlnum"] != 6 {
+		t.Fatalf("retailerAlnum = %d, want 6", breakdown["retailerAlnum"])
+	}
+	if breakdown["quarterMultiple"] != 25 {
+		t.Fatalf("quarterMultiple = %d, want 25", breakdown["quarterMultiple"])
+	}
+	if breakdown["oddDay"] != 6 {
+		t.Fatalf("oddDay = %d, want 6", breakdown["oddDay"])
+	}
+}
+
+func TestLoadRuleEngineUnknownType(t *testing.T) {
+	_, err := buildRuleEngine([]RuleConfig{{Type: "doesNotExist"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown rule type")
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rule is one scoring rule. Score inspects an already-validated receipt
+// and returns the points it contributes; Name identifies it in rule-set
+// config files and in the per-rule breakdown returned by the API. Because
+// NormalizedReceipt has already been through Validate, Score can never
+// fail on malformed input.
+type Rule interface {
+	Score(r *NormalizedReceipt) (int, error)
+	Name() string
+}
+
+// RetailerAlnumRule awards one point per alphanumeric character in the
+// retailer name, per the published scoring spec (spaces and punctuation
+// don't count).
+type RetailerAlnumRule struct{}
+
+func (RetailerAlnumRule) Name() string { return "retailerAlnum" }
+
+func (RetailerAlnumRule) Score(r *NormalizedReceipt) (int, error) {
+	points := 0
+	for _, c := range r.Retailer {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			points++
+		}
+	}
+	return points, nil
+}
+
+// RoundDollarRule awards Bonus points when the total has no cents.
+type RoundDollarRule struct {
+	Bonus int
+}
+
+func (RoundDollarRule) Name() string { return "roundDollar" }
+
+func (rule RoundDollarRule) Score(r *NormalizedReceipt) (int, error) {
+	if r.Total.Equal(r.Total.Truncate(0)) {
+		return rule.Bonus, nil
+	}
+	return 0, nil
+}
+
+// QuarterMultipleRule awards Bonus points when the total is a multiple of
+// a quarter (.00, .25, .50, .75).
+type QuarterMultipleRule struct {
+	Bonus int
+}
+
+func (QuarterMultipleRule) Name() string { return "quarterMultiple" }
+
+func (rule QuarterMultipleRule) Score(r *NormalizedReceipt) (int, error) {
+	quarter := decimal.NewFromFloat(0.25)
+	remainder := r.Total.Mod(quarter)
+	if remainder.IsZero() {
+		return rule.Bonus, nil
+	}
+	return 0, nil
+}
+
+// ItemPairRule awards Bonus points for every Per items on the receipt.
+type ItemPairRule struct {
+	Per   int
+	Bonus int
+}
+
+func (ItemPairRule) Name() string { return "itemPair" }
+
+func (rule ItemPairRule) Score(r *NormalizedReceipt) (int, error) {
+	if rule.Per <= 0 {
+		return 0, fmt.Errorf("itemPair rule: per must be positive, got %d", rule.Per)
+	}
+	return rule.Bonus * (len(r.Items) / rule.Per), nil
+}
+
+// DescriptionMultipleRule awards ceil(price*Multiplier) points for every
+// item whose trimmed description length is a multiple of Modulo.
+type DescriptionMultipleRule struct {
+	Modulo     int
+	Multiplier float64
+}
+
+func (DescriptionMultipleRule) Name() string { return "descriptionMultiple" }
+
+func (rule DescriptionMultipleRule) Score(r *NormalizedReceipt) (int, error) {
+	if rule.Modulo <= 0 {
+		return 0, fmt.Errorf("descriptionMultiple rule: modulo must be positive, got %d", rule.Modulo)
+	}
+
+	points := 0
+	for _, item := range r.Items {
+		description := strings.TrimSpace(item.ShortDescription)
+		if len(description)%rule.Modulo != 0 {
+			continue
+		}
+
+		price, _ := item.Price.Float64()
+		points += int(math.Ceil(price * rule.Multiplier))
+	}
+	return points, nil
+}
+
+// OddDayRule awards Bonus points when the day of the purchase date is odd.
+type OddDayRule struct {
+	Bonus int
+}
+
+func (OddDayRule) Name() string { return "oddDay" }
+
+func (rule OddDayRule) Score(r *NormalizedReceipt) (int, error) {
+	if r.PurchaseDate.Day()&0b1 == 1 {
+		return rule.Bonus, nil
+	}
+	return 0, nil
+}
+
+// TimeWindowRule awards Bonus points when the purchase time falls within
+// [Start, End] (both "15:04"), inclusive of both ends.
+type TimeWindowRule struct {
+	Start string
+	End   string
+	Bonus int
+}
+
+func (TimeWindowRule) Name() string { return "timeWindow" }
+
+func (rule TimeWindowRule) Score(r *NormalizedReceipt) (int, error) {
+	// PurchaseTime was parsed with the same "15:04" layout, so both share
+	// the same zero-value date and are directly comparable.
+	start, err := time.Parse(timeLayout, rule.Start)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := time.Parse(timeLayout, rule.End)
+	if err != nil {
+		return 0, err
+	}
+
+	purchaseTime := r.PurchaseTime
+	if !purchaseTime.Before(start) && !purchaseTime.After(end) {
+		return rule.Bonus, nil
+	}
+	return 0, nil
+}
+
+// PercentOfTotalRule awards Percent percent of the total, as points,
+// whenever the total reaches Threshold. It models a simple bonus tier for
+// big-ticket receipts.
+type PercentOfTotalRule struct {
+	Threshold float64
+	Percent   float64
+}
+
+func (PercentOfTotalRule) Name() string { return "percentOfTotal" }
+
+func (rule PercentOfTotalRule) Score(r *NormalizedReceipt) (int, error) {
+	total, _ := r.Total.Float64()
+	if total < rule.Threshold {
+		return 0, nil
+	}
+	return int(math.Round(total * rule.Percent / 100)), nil
+}
+
+// TaxRule aggregates every item's price inflated by VATPercent
+// (unitPrice*(1+VAT)) and awards one point per whole dollar of that
+// VAT-inclusive total, the same line-item-aggregation style used by
+// invoice processors.
+type TaxRule struct {
+	VATPercent float64
+}
+
+func (TaxRule) Name() string { return "tax" }
+
+func (rule TaxRule) Score(r *NormalizedReceipt) (int, error) {
+	vatMultiplier := decimal.NewFromFloat(1 + rule.VATPercent/100)
+
+	total := decimal.Zero
+	for _, item := range r.Items {
+		total = total.Add(item.Price.Mul(vatMultiplier))
+	}
+
+	points, _ := total.Float64()
+	return int(math.Floor(points)), nil
+}
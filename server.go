@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// serverConfig controls the http.Server's timeouts and the middleware
+// chain, all overridable via environment variables so ops can tune a
+// deployment without a rebuild.
+type serverConfig struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownGrace     time.Duration
+	ProcessRateRPS    float64
+	ProcessRateBurst  int
+}
+
+func loadServerConfig() serverConfig {
+	return serverConfig{
+		Addr:              envOr("ADDR", ":8080"),
+		ReadHeaderTimeout: envDurationOr("READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       envDurationOr("READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:      envDurationOr("WRITE_TIMEOUT", 20*time.Second),
+		IdleTimeout:       envDurationOr("IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:    envIntOr("MAX_HEADER_BYTES", 1<<20),
+		ShutdownGrace:     envDurationOr("SHUTDOWN_GRACE", 15*time.Second),
+		ProcessRateRPS:    envFloatOr("PROCESS_RATE_LIMIT_RPS", 5),
+		ProcessRateBurst:  envIntOr("PROCESS_RATE_LIMIT_BURST", 10),
+	}
+}
+
+func envIntOr(key string, fallback int) int {
+	if v, ok := os.LookupEnv(key); ok {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func envFloatOr(key string, fallback float64) float64 {
+	if v, ok := os.LookupEnv(key); ok {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports readiness by exercising the store; a deployment
+// fails readiness before it can serve traffic it has no hope of storing.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := store.List("", 1); err != nil {
+		http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ready"))
+}
+
+func serve() error {
+	cfg := loadServerConfig()
+	limiter := newPerIPRateLimiter(cfg.ProcessRateRPS, cfg.ProcessRateBurst)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /receipts/process", rateLimitMiddleware(limiter)(http.HandlerFunc(postProcessReceipt)))
+	mux.HandleFunc("GET /receipts/{id}/points", getPoints)
+	mux.HandleFunc("GET /receipts/{id}", handleGetReceipt)
+	mux.HandleFunc("PUT /receipts/{id}", handlePutReceipt)
+	mux.HandleFunc("DELETE /receipts/{id}", handleDeleteReceipt)
+	mux.HandleFunc("GET /receipts", requireBearerToken(adminToken, handleListReceipts))
+	mux.HandleFunc("GET /scoring/rules", handleScoringRules)
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /readyz", handleReadyz)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{}))
+
+	handler := chain(mux, requestIDMiddleware, recoveryMiddleware(accessLogger), accessLogMiddleware(accessLogger, metrics))
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Printf("Serving on %s\n", cfg.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		logger.Print("shutdown signal received, draining connections")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGrace)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return http.ErrServerClosed
+	}
+}
+
+var accessLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
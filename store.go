@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound carries the message the handlers send with a 404 when no
+// receipt is recorded for the given id. Store implementations do not return
+// it; Get reports a missing id through its bool.
+var ErrNotFound = errors.New("no receipt found for that id")
+
+// StoredReceipt is what a Store keeps per receipt id: the original
+// payload plus the points and breakdown it scored, so GET/PUT/DELETE can
+// all operate on the full record instead of just the point total.
+type StoredReceipt struct {
+	Receipt   Receipt        `json:"receipt"`
+	Points    int            `json:"points"`
+	Breakdown map[string]int `json:"breakdown,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// StoreRecord pairs a StoredReceipt with the id it's filed under, for List.
+type StoreRecord struct {
+	ID string `json:"id"`
+	StoredReceipt
+}
+
+// Store is the persistence boundary for processed receipts. Handlers talk
+// to a Store rather than touching any particular backend directly, so the
+// backend can be swapped (in-memory, embedded KV, distributed KV) without
+// touching the handlers.
+type Store interface {
+	// Get returns the record stored for id. The bool is false if no entry
+	// exists.
+	Get(id string) (*StoredReceipt, bool, error)
+	Put(id string, record *StoredReceipt) error
+	Delete(id string) error
+	// List returns up to limit records with id > cursor in id order,
+	// plus the cursor to pass for the next page ("" once exhausted).
+	List(cursor string, limit int) ([]StoreRecord, string, error)
+	Close() error
+}
+
+// StoreKind selects which Store implementation newStore constructs.
+type StoreKind string
+
+const (
+	StoreKindMemory    StoreKind = "memory"
+	StoreKindBolt      StoreKind = "bolt"
+	StoreKindJetStream StoreKind = "jetstream"
+)
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("receipts")
+
+// BoltStore is the single-node persistent Store, backed by an embedded
+// bbolt database file. It survives restarts without any external
+// dependency, which makes it the default choice for a standalone
+// deployment.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) the bbolt database at path
+// and ensures the receipts bucket exists.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(id string) (*StoredReceipt, bool, error) {
+	var record *StoredReceipt
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(boltBucket).Get([]byte(id))
+		if raw == nil {
+			return nil
+		}
+
+		record = &StoredReceipt{}
+		return json.Unmarshal(raw, record)
+	})
+
+	return record, record != nil, err
+}
+
+func (s *BoltStore) Put(id string, record *StoredReceipt) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(id), raw)
+	})
+}
+
+func (s *BoltStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(id))
+	})
+}
+
+func (s *BoltStore) List(cursor string, limit int) ([]StoreRecord, string, error) {
+	var records []StoreRecord
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+
+		var k, v []byte
+		if cursor == "" {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek([]byte(cursor))
+			if k != nil && string(k) == cursor {
+				k, v = c.Next()
+			}
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if limit > 0 && len(records) == limit {
+				break
+			}
+
+			record := StoredReceipt{}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records = append(records, StoreRecord{ID: string(k), StoredReceipt: record})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if limit > 0 && len(records) == limit {
+		next = records[len(records)-1].ID
+	}
+
+	return records, next, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultNATSURL is used when --nats-url / NATS_URL is not set.
+const DefaultNATSURL = nats.DefaultURL
+
+// JetStreamStore is the Store implementation for distributed deployments:
+// receipts live in a JetStream KV bucket, one bucket per environment,
+// keyed by receipt id. TTL, if set, lets old receipts age out of the
+// bucket automatically instead of growing it forever.
+type JetStreamStore struct {
+	nc  *nats.Conn
+	kv  jetstream.KeyValue
+	ctx context.Context
+}
+
+// NewJetStreamStore connects to natsURL and creates (or reuses) a KV
+// bucket named after env, e.g. "receipts-prod". ttl of zero means keys
+// never expire.
+func NewJetStreamStore(natsURL, env string, ttl time.Duration) (*JetStreamStore, error) {
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	ctx := context.Background()
+	bucket := "receipts-" + env
+
+	kv, err := js.KeyValue(ctx, bucket)
+	if err != nil {
+		kv, err = js.CreateKeyValue(ctx, jetstream.KeyValueConfig{
+			Bucket: bucket,
+			TTL:    ttl,
+		})
+	}
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	return &JetStreamStore{nc: nc, kv: kv, ctx: ctx}, nil
+}
+
+func (s *JetStreamStore) Get(id string) (*StoredReceipt, bool, error) {
+	entry, err := s.kv.Get(s.ctx, id)
+	if err == jetstream.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	record := &StoredReceipt{}
+	if err := json.Unmarshal(entry.Value(), record); err != nil {
+		return nil, false, err
+	}
+
+	return record, true, nil
+}
+
+func (s *JetStreamStore) Put(id string, record *StoredReceipt) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.kv.Put(s.ctx, id, raw)
+	return err
+}
+
+func (s *JetStreamStore) Delete(id string) error {
+	return s.kv.Delete(s.ctx, id)
+}
+
+func (s *JetStreamStore) List(cursor string, limit int) ([]StoreRecord, string, error) {
+	lister, err := s.kv.ListKeys(s.ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var ids []string
+	for id := range lister.Keys() {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	records := make([]StoreRecord, 0, len(ids))
+	for _, id := range ids {
+		record, ok, err := s.Get(id)
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			continue
+		}
+		records = append(records, StoreRecord{ID: id, StoredReceipt: *record})
+	}
+
+	next := ""
+	if limit > 0 && len(records) == limit {
+		next = records[len(records)-1].ID
+	}
+
+	return records, next, nil
+}
+
+func (s *JetStreamStore) Close() error {
+	s.nc.Close()
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is the original map-backed Store. Everything is lost on
+// restart unless snapshotting is enabled, which is why it periodically
+// writes its contents to a JSON file that NewMemoryStore can later load
+// back in during development.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string]StoredReceipt
+
+	snapshotPath string
+	stop         chan struct{}
+	stopped      sync.Once
+}
+
+// NewMemoryStore builds a MemoryStore. If snapshotPath is non-empty and a
+// snapshot already exists on disk, it is loaded to seed the map. When
+// snapshotInterval is greater than zero, a background goroutine writes the
+// current contents to snapshotPath on that interval until Close is called.
+func NewMemoryStore(snapshotPath string, snapshotInterval time.Duration) (*MemoryStore, error) {
+	s := &MemoryStore{
+		data:         make(map[string]StoredReceipt),
+		snapshotPath: snapshotPath,
+		stop:         make(chan struct{}),
+	}
+
+	if snapshotPath != "" {
+		if err := s.loadSnapshot(); err != nil {
+			return nil, err
+		}
+	}
+
+	if snapshotPath != "" && snapshotInterval > 0 {
+		go s.snapshotLoop(snapshotInterval)
+	}
+
+	return s, nil
+}
+
+func (s *MemoryStore) Get(id string) (*StoredReceipt, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.data[id]
+	if !ok {
+		return nil, false, nil
+	}
+	return &record, true, nil
+}
+
+func (s *MemoryStore) Put(id string, record *StoredReceipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[id] = *record
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, id)
+	return nil
+}
+
+func (s *MemoryStore) List(cursor string, limit int) ([]StoreRecord, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.data))
+	for id := range s.data {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	records := make([]StoreRecord, len(ids))
+	for i, id := range ids {
+		records[i] = StoreRecord{ID: id, StoredReceipt: s.data[id]}
+	}
+
+	next := ""
+	if limit > 0 && len(records) == limit {
+		next = records[len(records)-1].ID
+	}
+
+	return records, next, nil
+}
+
+func (s *MemoryStore) Close() error {
+	s.stopped.Do(func() { close(s.stop) })
+
+	if s.snapshotPath != "" {
+		return s.writeSnapshot()
+	}
+	return nil
+}
+
+func (s *MemoryStore) loadSnapshot() error {
+	raw, err := os.ReadFile(s.snapshotPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Unmarshal(raw, &s.data)
+}
+
+func (s *MemoryStore) writeSnapshot() error {
+	s.mu.RLock()
+	raw, err := json.Marshal(s.data)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.snapshotPath, raw, 0o644)
+}
+
+func (s *MemoryStore) snapshotLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.writeSnapshot(); err != nil {
+				logger.Printf("snapshot failed: %s", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
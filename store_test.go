@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// storeFactories builds every backend that can run in this test binary
+// without an external dependency. JetStreamStore needs a reachable NATS
+// server, so it's only included when NATS_URL is set in the environment.
+func storeFactories(t *testing.T) map[string]func() Store {
+	t.Helper()
+
+	factories := map[string]func() Store{
+		"memory": func() Store {
+			s, err := NewMemoryStore("", 0)
+			if err != nil {
+				t.Fatalf("NewMemoryStore: %s", err)
+			}
+			return s
+		},
+		"bolt": func() Store {
+			s, err := NewBoltStore(filepath.Join(t.TempDir(), "receipts.db"))
+			if err != nil {
+				t.Fatalf("NewBoltStore: %s", err)
+			}
+			return s
+		},
+	}
+
+	if url := os.Getenv("NATS_URL"); url != "" {
+		factories["jetstream"] = func() Store {
+			s, err := NewJetStreamStore(url, "test", 0)
+			if err != nil {
+				t.Fatalf("NewJetStreamStore: %s", err)
+			}
+			return s
+		}
+	}
+
+	return factories
+}
+
+func sampleRecord(points int) *StoredReceipt {
+	return &StoredReceipt{
+		Receipt:   Receipt{Retailer: "Target", Total: "1.25"},
+		Points:    points,
+		Breakdown: map[string]int{"retailerAlnum": points},
+		CreatedAt: time.Unix(0, 0).UTC(),
+	}
+}
+
+// TestStoreBehavior runs the same behavioral assertions against every
+// registered backend so a new implementation can't drift from the
+// Store contract.
+func TestStoreBehavior(t *testing.T) {
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			defer s.Close()
+
+			if _, ok, err := s.Get("missing"); err != nil || ok {
+				t.Fatalf("Get(missing) = (_, %v, %v), want (_, false, nil)", ok, err)
+			}
+
+			if err := s.Put("abc", sampleRecord(42)); err != nil {
+				t.Fatalf("Put: %s", err)
+			}
+
+			record, ok, err := s.Get("abc")
+			if err != nil || !ok || record.Points != 42 {
+				t.Fatalf("Get(abc) = (%v, %v, %v), want (42, true, nil)", record, ok, err)
+			}
+
+			if err := s.Put("abc", sampleRecord(43)); err != nil {
+				t.Fatalf("Put overwrite: %s", err)
+			}
+			if record, _, _ := s.Get("abc"); record.Points != 43 {
+				t.Fatalf("Get(abc) after overwrite = %d, want 43", record.Points)
+			}
+
+			if err := s.Delete("abc"); err != nil {
+				t.Fatalf("Delete: %s", err)
+			}
+			if _, ok, _ := s.Get("abc"); ok {
+				t.Fatalf("Get(abc) after Delete found an entry, want none")
+			}
+		})
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			defer s.Close()
+
+			for _, id := range []string{"a", "b", "c"} {
+				if err := s.Put(id, sampleRecord(1)); err != nil {
+					t.Fatalf("Put(%s): %s", id, err)
+				}
+			}
+
+			page, next, err := s.List("", 2)
+			if err != nil {
+				t.Fatalf("List: %s", err)
+			}
+			if len(page) != 2 {
+				t.Fatalf("len(page) = %d, want 2", len(page))
+			}
+			if next != page[len(page)-1].ID {
+				t.Fatalf("next cursor = %q, want %q", next, page[len(page)-1].ID)
+			}
+
+			rest, next, err := s.List(next, 2)
+			if err != nil {
+				t.Fatalf("List page 2: %s", err)
+			}
+			if len(rest) != 1 {
+				t.Fatalf("len(rest) = %d, want 1", len(rest))
+			}
+			if next != "" {
+				t.Fatalf("next cursor = %q, want empty once exhausted", next)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.json")
+
+	s1, err := NewMemoryStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewMemoryStore: %s", err)
+	}
+	if err := s1.Put("abc", sampleRecord(7)); err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+	if err := s1.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	s2, err := NewMemoryStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewMemoryStore reload: %s", err)
+	}
+	defer s2.Close()
+
+	record, ok, err := s2.Get("abc")
+	if err != nil || !ok || record.Points != 7 {
+		t.Fatalf("Get(abc) after reload = (%v, %v, %v), want (7, true, nil)", record, ok, err)
+	}
+}
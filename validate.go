@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	retailerPattern = regexp.MustCompile(`^[\w\s\-&]+$`)
+	moneyPattern    = regexp.MustCompile(`^\d+\.\d{2}$`)
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+// NormalizedReceipt is a Receipt that has already passed Validate: dates,
+// times and money are parsed into real types so scoring rules can't hit a
+// parse error on malformed input.
+type NormalizedReceipt struct {
+	Retailer     string
+	PurchaseDate time.Time
+	PurchaseTime time.Time
+	Items        []NormalizedItem
+	Total        decimal.Decimal
+}
+
+type NormalizedItem struct {
+	ShortDescription string
+	Price            decimal.Decimal
+}
+
+// ValidationError describes one field that failed validation, in the
+// shape returned to API clients.
+type ValidationError struct {
+	Error   string `json:"error"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors accumulates every field that failed validation so a
+// client can fix them all in one round-trip instead of one at a time.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Message
+	}
+	return fmt.Sprintf("%d validation errors", len(errs))
+}
+
+func (errs *ValidationErrors) add(field, message string) {
+	*errs = append(*errs, ValidationError{Error: "invalid_field", Field: field, Message: message})
+}
+
+// Validate checks r against the published API spec. On success it returns
+// the NormalizedReceipt ready for scoring; on failure it returns every
+// field that failed, not just the first.
+func Validate(r *Receipt) (*NormalizedReceipt, ValidationErrors) {
+	var errs ValidationErrors
+	normalized := &NormalizedReceipt{}
+
+	if retailerPattern.MatchString(r.Retailer) {
+		normalized.Retailer = r.Retailer
+	} else {
+		errs.add("retailer", `must match ^[\w\s\-&]+$`)
+	}
+
+	if moneyPattern.MatchString(r.Total) {
+		normalized.Total, _ = decimal.NewFromString(r.Total)
+	} else {
+		errs.add("total", `must match ^\d+\.\d{2}$`)
+	}
+
+	if date, err := time.Parse(dateLayout, r.PurchaseDate); err != nil {
+		errs.add("purchaseDate", "must be a valid date in YYYY-MM-DD format")
+	} else {
+		normalized.PurchaseDate = date
+	}
+
+	if t, err := time.Parse(timeLayout, r.PurchaseTime); err != nil {
+		errs.add("purchaseTime", "must be a valid time in HH:MM format")
+	} else {
+		normalized.PurchaseTime = t
+	}
+
+	if len(r.Items) == 0 {
+		errs.add("items", "must contain at least one item")
+	}
+
+	normalized.Items = make([]NormalizedItem, 0, len(r.Items))
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d].price", i)
+
+		ni := NormalizedItem{ShortDescription: item.ShortDescription}
+		if moneyPattern.MatchString(item.Price) {
+			ni.Price, _ = decimal.NewFromString(item.Price)
+		} else {
+			errs.add(field, `must match ^\d+\.\d{2}$`)
+		}
+		normalized.Items = append(normalized.Items, ni)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return normalized, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestValidateValid(t *testing.T) {
+	r := &Receipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        "35.35",
+		Items: []Item{
+			{ShortDescription: "Pepsi - 12-oz", Price: "1.25"},
+		},
+	}
+
+	normalized, errs := Validate(r)
+	if errs != nil {
+		t.Fatalf("Validate returned errors for a valid receipt: %v", errs)
+	}
+	if normalized.Retailer != "Target" {
+		t.Fatalf("Retailer = %q, want %q", normalized.Retailer, "Target")
+	}
+}
+
+func TestValidateAccumulatesErrors(t *testing.T) {
+	r := &Receipt{
+		Retailer:     "Target",
+		PurchaseDate: "not-a-date",
+		PurchaseTime: "not-a-time",
+		Total:        "not-money",
+		Items:        nil,
+	}
+
+	_, errs := Validate(r)
+	if len(errs) != 4 {
+		t.Fatalf("got %d validation errors, want 4: %v", len(errs), errs)
+	}
+}
+
+func TestValidateItemPriceField(t *testing.T) {
+	r := &Receipt{
+		Retailer:     "Target",
+		PurchaseDate: "2022-01-01",
+		PurchaseTime: "13:01",
+		Total:        "1.25",
+		Items: []Item{
+			{ShortDescription: "Pepsi", Price: "1.25"},
+			{ShortDescription: "Chips", Price: "bad"},
+		},
+	}
+
+	_, errs := Validate(r)
+	if len(errs) != 1 {
+		t.Fatalf("got %d validation errors, want 1: %v", len(errs), errs)
+	}
+	if errs[0].Field != "items[1].price" {
+		t.Fatalf("Field = %q, want %q", errs[0].Field, "items[1].price")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// TestVector is one conformance corpus entry: a receipt plus the points
+// (and per-rule breakdown) it should score against the default rule set,
+// or ExpectInvalid if the receipt is expected to fail validation instead.
+type TestVector struct {
+	Description       string         `json:"description"`
+	Receipt           Receipt        `json:"receipt"`
+	ExpectInvalid     bool           `json:"expectInvalid,omitempty"`
+	ExpectedPoints    int            `json:"expectedPoints,omitempty"`
+	ExpectedBreakdown map[string]int `json:"expectedBreakdown,omitempty"`
+}
+
+// loadVectorFiles returns every *.json file in dir, sorted, so test output
+// and -record runs are deterministic.
+func loadVectorFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func loadVector(path string) (*TestVector, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var v TestVector
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("parsing vector %s: %w", path, err)
+	}
+	return &v, nil
+}
+
+func saveVector(path string, v *TestVector) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// checkVector scores v.Receipt against the default rule engine and
+// compares the result to v's expected fields. It returns a non-nil error
+// describing the first mismatch found.
+func checkVector(v *TestVector) error {
+	normalized, errs := Validate(&v.Receipt)
+	if v.ExpectInvalid {
+		if errs == nil {
+			return fmt.Errorf("expected validation to fail, but it succeeded")
+		}
+		return nil
+	}
+	if errs != nil {
+		return fmt.Errorf("expected a valid receipt, got validation errors: %v", errs)
+	}
+
+	points, breakdown, err := DefaultRuleEngine().Score(normalized)
+	if err != nil {
+		return fmt.Errorf("scoring: %w", err)
+	}
+
+	if points != v.ExpectedPoints {
+		return fmt.Errorf("points = %d, want %d", points, v.ExpectedPoints)
+	}
+	if v.ExpectedBreakdown != nil && !reflect.DeepEqual(breakdown, v.ExpectedBreakdown) {
+		return fmt.Errorf("breakdown = %v, want %v", breakdown, v.ExpectedBreakdown)
+	}
+
+	return nil
+}
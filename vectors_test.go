@@ -0,0 +1,32 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const vectorsDir = "testvectors"
+
+func TestConformanceVectors(t *testing.T) {
+	paths, err := loadVectorFiles(vectorsDir)
+	if err != nil {
+		t.Fatalf("loadVectorFiles: %s", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no vectors found in %s", vectorsDir)
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			v, err := loadVector(path)
+			if err != nil {
+				t.Fatalf("loadVector: %s", err)
+			}
+
+			if err := checkVector(v); err != nil {
+				t.Fatalf("%s: %s", v.Description, err)
+			}
+		})
+	}
+}